@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"chatplus/core"
+	"chatplus/core/har"
+	"chatplus/core/types"
+	"chatplus/store/model"
+	"chatplus/utils/resp"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// HarHandler 管理员维护 ChatGPT Web 逆向代理所使用的 .har 文件池
+type HarHandler struct {
+	BaseHandler
+	db   *gorm.DB
+	pool *har.Pool
+}
+
+func NewHarHandler(app *core.AppServer, db *gorm.DB, pool *har.Pool) *HarHandler {
+	h := &HarHandler{db: db, pool: pool}
+	h.App = app
+	return h
+}
+
+// reload 重新从数据库加载全部已启用的 HAR 文件到内存轮询池
+func (h *HarHandler) reload() error {
+	var files []model.HarFile
+	res := h.db.Where("enabled = ?", true).Find(&files)
+	if res.Error != nil {
+		return res.Error
+	}
+	h.pool.Load(files)
+	return nil
+}
+
+// Add 上传一份 .har 文件，解析校验通过后入库并加入轮询池
+func (h *HarHandler) Add(c *gin.Context) {
+	var data struct {
+		FileName string `json:"file_name"`
+		Content  string `json:"content"`
+		Email    string `json:"email"`
+	}
+	if err := c.ShouldBindJSON(&data); err != nil {
+		resp.ERROR(c, types.InvalidArgs)
+		return
+	}
+
+	if _, err := har.Parse(0, data.Content); err != nil {
+		resp.ERROR(c, fmt.Sprintf("invalid har file: %v", err))
+		return
+	}
+
+	now := time.Now().Unix()
+	item := model.HarFile{
+		FileName:   data.FileName,
+		RawContent: data.Content,
+		Email:      data.Email,
+		Enabled:    true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	res := h.db.Create(&item)
+	if res.Error != nil {
+		resp.ERROR(c, fmt.Sprintf("error with save har file: %v", res.Error))
+		return
+	}
+
+	if err := h.reload(); err != nil {
+		resp.ERROR(c, fmt.Sprintf("error with reload har pool: %v", err))
+		return
+	}
+	resp.SUCCESS(c)
+}
+
+// List 列出当前已上传的 HAR 文件（不包含原始内容，避免返回体过大）
+func (h *HarHandler) List(c *gin.Context) {
+	var items []model.HarFile
+	res := h.db.Omit("raw_content").Order("id desc").Find(&items)
+	if res.Error != nil {
+		resp.ERROR(c, fmt.Sprintf("error with query har files: %v", res.Error))
+		return
+	}
+	resp.SUCCESS(c, items)
+}
+
+// Remove 删除一份 HAR 文件并刷新轮询池
+func (h *HarHandler) Remove(c *gin.Context) {
+	var data struct {
+		Id uint `json:"id"`
+	}
+	if err := c.ShouldBindJSON(&data); err != nil {
+		resp.ERROR(c, types.InvalidArgs)
+		return
+	}
+
+	res := h.db.Delete(&model.HarFile{}, data.Id)
+	if res.Error != nil {
+		resp.ERROR(c, fmt.Sprintf("error with remove har file: %v", res.Error))
+		return
+	}
+
+	if err := h.reload(); err != nil {
+		resp.ERROR(c, fmt.Sprintf("error with reload har pool: %v", err))
+		return
+	}
+	resp.SUCCESS(c)
+}
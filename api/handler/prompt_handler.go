@@ -2,53 +2,43 @@ package handler
 
 import (
 	"chatplus/core"
+	"chatplus/core/har"
+	"chatplus/core/llm"
 	"chatplus/core/types"
 	"chatplus/store/model"
 	"chatplus/utils/resp"
+	"context"
 	"fmt"
 
-	"github.com/imroc/req/v3"
-
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
-const rewritePromptTemplate = "Please rewrite the following text into AI painting prompt words, and please try to add detailed description of the picture, painting style, scene, rendering effect, picture light and other elements. Please output directly in English without any explanation, within 150 words. The text to be rewritten is: [%s]"
+const rewritePromptTemplate = "Help me turn the following idea into an AI painting prompt by calling the rewrite_painting_prompt tool. The idea is: [%s]"
 const translatePromptTemplate = "Translate the following painting prompt words into English keyword phrases. Without any explanation, directly output the keyword phrases separated by commas. The content to be translated is: [%s]"
 
+// rewriteFallbackPromptTemplate is used instead of rewritePromptTemplate when
+// falling back to the HAR reverse proxy, which only ever returns free text and
+// has no way to invoke the rewrite_painting_prompt tool
+const rewriteFallbackPromptTemplate = "Turn the following idea into an AI painting prompt: a short, comma-separated English keyword phrase describing subject, style, scene, lighting and camera. Without any explanation, directly output the keyword phrases. The idea is: [%s]"
+
 type PromptHandler struct {
 	BaseHandler
-	db *gorm.DB
+	db       *gorm.DB
+	harPool  *har.Pool
+	registry *llm.Registry
+	router   *llm.Router
 }
 
-func NewPromptHandler(app *core.AppServer, db *gorm.DB) *PromptHandler {
-	h := &PromptHandler{db: db}
+func NewPromptHandler(app *core.AppServer, db *gorm.DB, harPool *har.Pool, registry *llm.Registry, router *llm.Router) *PromptHandler {
+	h := &PromptHandler{db: db, harPool: harPool, registry: registry, router: router}
 	h.App = app
 	return h
 }
 
-type apiRes struct {
-	Model   string `json:"model"`
-	Choices []struct {
-		Index   int `json:"index"`
-		Message struct {
-			Role    string `json:"role"`
-			Content string `json:"content"`
-		} `json:"message"`
-		FinishReason string `json:"finish_reason"`
-	} `json:"choices"`
-}
-
-type apiErrRes struct {
-	Error struct {
-		Code    interface{} `json:"code"`
-		Message string      `json:"message"`
-		Param   interface{} `json:"param"`
-		Type    string      `json:"type"`
-	} `json:"error"`
-}
-
-// Rewrite translate and rewrite prompt with ChatGPT
+// Rewrite translate and rewrite prompt with ChatGPT. Captcha verification for
+// this endpoint happens once, in the server's CaptchaMiddleware, so it isn't
+// repeated here.
 func (h *PromptHandler) Rewrite(c *gin.Context) {
 	var data struct {
 		Prompt string `json:"prompt"`
@@ -58,9 +48,36 @@ func (h *PromptHandler) Rewrite(c *gin.Context) {
 		return
 	}
 
-	content, err := h.request(data.Prompt, rewritePromptTemplate)
+	chat, err := h.resolveChat()
 	if err != nil {
-		resp.ERROR(c, err.Error())
+		// 没有配置可用的 API KEY，走 ChatGPT 网页版逆向代理通道
+		content, err := h.fallbackOrError(rewriteFallbackPromptTemplate, data.Prompt, err)
+		if err != nil {
+			resp.ERROR(c, err.Error())
+			return
+		}
+		resp.SUCCESS(c, content)
+		return
+	}
+
+	chatReq := llm.ChatRequest{
+		Model:       "gpt-3.5-turbo",
+		Temperature: 0.9,
+		MaxTokens:   1024,
+		Messages: []types.Message{{
+			Role:    "user",
+			Content: fmt.Sprintf(rewritePromptTemplate, data.Prompt),
+		}},
+	}
+	content, err := h.requestWithTool(chatReq, chat)
+	if err != nil {
+		// 调用失败（欠费、被 Cloudflare 拦截等），同样走逆向代理通道兜底
+		content, err = h.fallbackOrError(rewriteFallbackPromptTemplate, data.Prompt, fmt.Errorf("error with http request: %v", err))
+		if err != nil {
+			resp.ERROR(c, err.Error())
+			return
+		}
+		resp.SUCCESS(c, content)
 		return
 	}
 
@@ -85,36 +102,62 @@ func (h *PromptHandler) Translate(c *gin.Context) {
 	resp.SUCCESS(c, content)
 }
 
-func (h *PromptHandler) request(prompt string, promptTemplate string) (string, error) {
-	// 获取 OpenAI 的 API KEY
+// resolveChat picks how to reach an LLM: the model-router when enabled,
+// otherwise the provider registered for the configured OpenAI API key's platform
+func (h *PromptHandler) resolveChat() (func(llm.ChatRequest) (llm.ChatResponse, error), error) {
+	// 模型路由模式下不依赖某一个固定的 API KEY，由 Router 自行挑选最便宜的健康 Provider
+	if h.App.ChatConfig.UseModelRouter {
+		return func(req llm.ChatRequest) (llm.ChatResponse, error) {
+			return h.router.Chat(context.Background(), req)
+		}, nil
+	}
+
+	// 获取 OpenAI 的 API KEY，再按 Platform 从注册表里找到对应的 Provider 实现
 	var apiKey model.ApiKey
 	res := h.db.Where("platform = ?", types.OpenAI).First(&apiKey)
 	if res.Error != nil {
-		return "", fmt.Errorf("error with fetch OpenAI API KEY：%v", res.Error)
+		return nil, fmt.Errorf("error with fetch OpenAI API KEY：%v", res.Error)
 	}
 
-	messages := make([]interface{}, 1)
-	messages[0] = types.Message{
-		Role:    "user",
-		Content: fmt.Sprintf(promptTemplate, prompt),
+	provider, err := h.registry.Get(apiKey.Platform)
+	if err != nil {
+		return nil, err
 	}
+	return func(req llm.ChatRequest) (llm.ChatResponse, error) {
+		return provider.Chat(context.Background(), req)
+	}, nil
+}
 
-	var response apiRes
-	var errRes apiErrRes
-	r, err := req.C().SetProxyURL(h.App.Config.ProxyURL).R().SetHeader("Content-Type", "application/json").
-		SetHeader("Authorization", "Bearer "+apiKey.Value).
-		SetBody(types.ApiRequest{
-			Model:       "gpt-3.5-turbo",
-			Temperature: 0.9,
-			MaxTokens:   1024,
-			Stream:      false,
-			Messages:    messages,
-		}).
-		SetErrorResult(&errRes).
-		SetSuccessResult(&response).Post(h.App.ChatConfig.OpenAI.ApiURL)
-	if err != nil || r.IsErrorState() {
-		return "", fmt.Errorf("error with http request: %v%v%s", err, r.Err, errRes.Error.Message)
+func (h *PromptHandler) request(prompt string, promptTemplate string) (string, error) {
+	chatReq := llm.ChatRequest{
+		Model:       "gpt-3.5-turbo",
+		Temperature: 0.9,
+		MaxTokens:   1024,
+		Messages: []types.Message{{
+			Role:    "user",
+			Content: fmt.Sprintf(promptTemplate, prompt),
+		}},
 	}
 
-	return response.Choices[0].Message.Content, nil
+	chat, err := h.resolveChat()
+	if err != nil {
+		// 没有配置可用的 API KEY，走 ChatGPT 网页版逆向代理通道
+		return h.fallbackOrError(promptTemplate, prompt, err)
+	}
+
+	response, err := chat(chatReq)
+	if err != nil {
+		// API KEY 存在但调用失败（欠费、被 Cloudflare 拦截等），同样走逆向代理通道兜底
+		return h.fallbackOrError(promptTemplate, prompt, fmt.Errorf("error with http request: %v", err))
+	}
+	return response.Content, nil
+}
+
+// fallbackOrError falls back to the HAR reverse-proxy pool when it's enabled,
+// otherwise surfaces origErr as-is
+func (h *PromptHandler) fallbackOrError(promptTemplate string, prompt string, origErr error) (string, error) {
+	if h.App.ChatConfig.OpenAI.UseReverseProxy {
+		return h.requestReverseProxy(fmt.Sprintf(promptTemplate, prompt))
+	}
+	return "", origErr
 }
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"bufio"
+	"chatplus/core/captcha"
+	"chatplus/core/har"
+	"chatplus/utils"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/imroc/req/v3"
+)
+
+const chatGptConversationURL = "https://chatgpt.com/backend-api/conversation"
+
+// conversationReq 是 ChatGPT 网页端 /backend-api/conversation 接口所需的消息树结构，
+// 这里只构造一轮单消息对话，不维护多轮上下文
+type conversationReq struct {
+	Action          string                `json:"action"`
+	Messages        []conversationMessage `json:"messages"`
+	Model           string                `json:"model"`
+	ParentMessageID string                `json:"parent_message_id"`
+	ArkoseToken     string                `json:"arkose_token,omitempty"`
+}
+
+type conversationMessage struct {
+	Id     string `json:"id"`
+	Author struct {
+		Role string `json:"role"`
+	} `json:"author"`
+	Content struct {
+		ContentType string   `json:"content_type"`
+		Parts       []string `json:"parts"`
+	} `json:"content"`
+}
+
+// conversationEvent 是逆向代理接口返回的 SSE 数据片段
+type conversationEvent struct {
+	Message struct {
+		Content struct {
+			Parts []string `json:"parts"`
+		} `json:"content"`
+	} `json:"message"`
+}
+
+// requestReverseProxy 通过 HAR 文件池拿到的哨兵请求头，以网页版会话的方式请求 ChatGPT，
+// 并把 SSE 流拼接还原成一次性的回答文本
+func (h *PromptHandler) requestReverseProxy(prompt string) (string, error) {
+	ctx, err := h.harPool.NextHeaderSet()
+	if err != nil {
+		return "", fmt.Errorf("error with acquire har context: %v", err)
+	}
+
+	arkoseToken, err := h.solveArkose(ctx)
+	if err != nil {
+		h.harPool.MarkFailure(ctx.FileId, 0)
+		return "", fmt.Errorf("error with solve arkose challenge: %v", err)
+	}
+
+	msg := conversationMessage{Id: utils.RandString(32)}
+	msg.Author.Role = "user"
+	msg.Content.ContentType = "text"
+	msg.Content.Parts = []string{prompt}
+
+	body := conversationReq{
+		Action:          "next",
+		Messages:        []conversationMessage{msg},
+		Model:           "text-davinci-002-render-sse",
+		ParentMessageID: utils.RandString(32),
+		ArkoseToken:     arkoseToken,
+	}
+
+	r, err := req.C().SetProxyURL(h.App.Config.ProxyURL).R().
+		SetHeader("Content-Type", "application/json").
+		SetHeader("Accept", "text/event-stream").
+		SetHeader("openai-sentinel-chat-requirements-token", ctx.ChatRequirementsToken).
+		SetHeader("openai-sentinel-turnstile", ctx.TurnstileToken).
+		SetHeader("openai-sentinel-proof-token", ctx.ProofToken).
+		SetHeader("oai-device-id", ctx.DeviceId).
+		SetHeader("openai-sentinel-arkose-token", arkoseToken).
+		SetBody(body).
+		SetSuccessResult(nil). // 响应体是 SSE 流，下面手动读取
+		Post(chatGptConversationURL)
+	if err != nil {
+		h.harPool.MarkFailure(ctx.FileId, 0)
+		return "", fmt.Errorf("error with http request: %v", err)
+	}
+	if r.IsErrorState() {
+		h.harPool.MarkFailure(ctx.FileId, r.StatusCode)
+		return "", fmt.Errorf("error with reverse proxy request, status: %d", r.StatusCode)
+	}
+
+	content, err := readSSEFinalContent(r.String())
+	if err != nil {
+		return "", err
+	}
+
+	h.harPool.MarkSuccess(ctx.FileId)
+	return content, nil
+}
+
+// solveArkose 用 HAR 里抓到的 public_key/bda 换取一个新鲜的 arkose token。
+// bda 指纹 blob 是录制 HAR 时浏览器那次挑战请求里原样带出来的，不是实时生成的，
+// 所以这仍然只是「尽力而为」的兜底方案：如果该 HAR 根本没有 arkose 挑战记录
+// （ArkoseChallengeURL 为空），说明这个账号/场景当时没有触发人机验证，直接跳过
+func (h *PromptHandler) solveArkose(ctx har.HarContext) (string, error) {
+	if ctx.ArkoseChallengeURL == "" {
+		return "", nil
+	}
+	return captcha.SolveArkose(ctx.ArkosePublicKey, ctx.ArkoseBda, h.App.Config.ProxyURL)
+}
+
+// readSSEFinalContent 解析 `data: {...}` 形式的 SSE 流，返回最后一次出现的完整回答内容
+func readSSEFinalContent(raw string) (string, error) {
+	scanner := bufio.NewScanner(strings.NewReader(raw))
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+
+	var content string
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "" || data == "[DONE]" {
+			continue
+		}
+
+		var event conversationEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			continue
+		}
+		if len(event.Message.Content.Parts) > 0 {
+			content = event.Message.Content.Parts[0]
+		}
+	}
+
+	if content == "" {
+		return "", fmt.Errorf("empty response from reverse proxy")
+	}
+	return content, nil
+}
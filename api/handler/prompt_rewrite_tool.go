@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"chatplus/core/llm"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const rewritePromptFunctionName = "rewrite_painting_prompt"
+
+// rewritePromptArgs is what the model fills in through function calling,
+// replacing the old "please output English within 150 words" instruction
+// with a structured schema. Rewrite joins the fields into one string with
+// assemble and sends only that string to the client; the individual fields
+// are not returned.
+type rewritePromptArgs struct {
+	Subject         string   `json:"subject"`
+	Style           string   `json:"style"`
+	Scene           string   `json:"scene"`
+	Lighting        string   `json:"lighting"`
+	Camera          string   `json:"camera"`
+	NegativePrompts []string `json:"negative_prompts"`
+	AspectRatio     string   `json:"aspect_ratio"`
+}
+
+// rewritePromptTool describes rewrite_painting_prompt as an OpenAI function tool
+func rewritePromptTool() llm.ToolDef {
+	return llm.ToolDef{
+		Type: "function",
+		Function: llm.ToolFunctionDef{
+			Name:        rewritePromptFunctionName,
+			Description: "Structure a user's free-text idea into an AI painting prompt",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"subject":          map[string]interface{}{"type": "string", "description": "The main subject of the picture"},
+					"style":            map[string]interface{}{"type": "string", "description": "The painting/rendering style"},
+					"scene":            map[string]interface{}{"type": "string", "description": "The scene or background"},
+					"lighting":         map[string]interface{}{"type": "string", "description": "The lighting of the picture"},
+					"camera":           map[string]interface{}{"type": "string", "description": "Camera angle/lens description"},
+					"negative_prompts": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": "Things that should NOT appear in the picture"},
+					"aspect_ratio":     map[string]interface{}{"type": "string", "description": "Aspect ratio of the picture, e.g. 16:9"},
+				},
+				"required": []string{"subject", "style", "scene"},
+			},
+		},
+	}
+}
+
+// assemble joins the structured fields back into a single English prompt string
+func (a rewritePromptArgs) assemble() string {
+	parts := make([]string, 0, 6)
+	for _, v := range []string{a.Subject, a.Style, a.Scene, a.Lighting, a.Camera} {
+		if v != "" {
+			parts = append(parts, v)
+		}
+	}
+	prompt := strings.Join(parts, ", ")
+	if len(a.NegativePrompts) > 0 {
+		prompt = fmt.Sprintf("%s --no %s", prompt, strings.Join(a.NegativePrompts, ", "))
+	}
+	if a.AspectRatio != "" {
+		prompt = fmt.Sprintf("%s --ar %s", prompt, a.AspectRatio)
+	}
+	return prompt
+}
+
+// requestWithTool asks the provider to call rewrite_painting_prompt and
+// assembles the final prompt string from its arguments instead of trusting
+// the raw assistant content
+func (h *PromptHandler) requestWithTool(chatReq llm.ChatRequest, chat func(llm.ChatRequest) (llm.ChatResponse, error)) (string, error) {
+	chatReq.Tools = []llm.ToolDef{rewritePromptTool()}
+	chatReq.ToolChoice = map[string]interface{}{
+		"type":     "function",
+		"function": map[string]interface{}{"name": rewritePromptFunctionName},
+	}
+
+	response, err := chat(chatReq)
+	if err != nil {
+		return "", err
+	}
+	if len(response.ToolCalls) == 0 {
+		return "", fmt.Errorf("model did not call %s", rewritePromptFunctionName)
+	}
+
+	var args rewritePromptArgs
+	if err := json.Unmarshal([]byte(response.ToolCalls[0].Arguments), &args); err != nil {
+		return "", fmt.Errorf("error with parsing tool call arguments: %v", err)
+	}
+	return args.assemble(), nil
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"chatplus/core/llm"
+	"chatplus/core/types"
+	"chatplus/store/model"
+	"chatplus/utils/resp"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/gin-gonic/gin"
+)
+
+// resolveStream mirrors resolveChat: the model-router when enabled, otherwise
+// the provider registered for the configured OpenAI API key's platform, so
+// RewriteStream/TranslateStream get the same cheapest-healthy-provider
+// fallback as the non-streaming Rewrite/Translate
+func (h *PromptHandler) resolveStream() (func(llm.ChatRequest) (<-chan llm.Delta, error), error) {
+	if h.App.ChatConfig.UseModelRouter {
+		return func(req llm.ChatRequest) (<-chan llm.Delta, error) {
+			return h.router.Stream(context.Background(), req)
+		}, nil
+	}
+
+	var apiKey model.ApiKey
+	res := h.db.Where("platform = ?", types.OpenAI).First(&apiKey)
+	if res.Error != nil {
+		return nil, fmt.Errorf("error with fetch OpenAI API KEY：%v", res.Error)
+	}
+
+	provider, err := h.registry.Get(apiKey.Platform)
+	if err != nil {
+		return nil, err
+	}
+	return func(req llm.ChatRequest) (<-chan llm.Delta, error) {
+		return provider.Stream(context.Background(), req)
+	}, nil
+}
+
+// streamPrompt runs promptTemplate through the configured provider and
+// forwards every token delta to the client as it arrives
+func (h *PromptHandler) streamPrompt(c *gin.Context, prompt string, promptTemplate string) {
+	stream, err := h.resolveStream()
+	if err != nil {
+		// 没有配置可用的 API KEY，走 ChatGPT 网页版逆向代理通道
+		h.streamFallbackOrError(c, promptTemplate, prompt, err)
+		return
+	}
+
+	chatReq := llm.ChatRequest{
+		Model:       "gpt-3.5-turbo",
+		Temperature: 0.9,
+		MaxTokens:   1024,
+		Messages: []types.Message{{
+			Role:    "user",
+			Content: fmt.Sprintf(promptTemplate, prompt),
+		}},
+	}
+
+	deltaCh, err := stream(chatReq)
+	if err != nil {
+		// 调用失败（欠费、被 Cloudflare 拦截等），同样走逆向代理通道兜底
+		h.streamFallbackOrError(c, promptTemplate, prompt, fmt.Errorf("error with http request: %v", err))
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+
+	c.Stream(func(w io.Writer) bool {
+		delta, ok := <-deltaCh
+		if !ok {
+			return false
+		}
+		if delta.Err != nil {
+			c.SSEvent("error", delta.Err.Error())
+			return false
+		}
+		c.SSEvent("message", delta.Content)
+		return true
+	})
+}
+
+// streamFallbackOrError mirrors fallbackOrError for the SSE endpoints. The
+// HAR reverse proxy has no streaming API of its own - it only ever returns
+// the fully assembled answer - so a successful fallback is emitted as a
+// single SSE message instead of token-by-token deltas
+func (h *PromptHandler) streamFallbackOrError(c *gin.Context, promptTemplate string, prompt string, origErr error) {
+	content, err := h.fallbackOrError(promptTemplate, prompt, origErr)
+	if err != nil {
+		resp.ERROR(c, err.Error())
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.SSEvent("message", content)
+}
+
+// RewriteStream is the SSE variant of Rewrite. streamPrompt has no way to set
+// Tools/ToolChoice on a mid-stream chatReq or to assemble tool-call argument
+// deltas, so it can't reuse rewritePromptTemplate (which tells the model to
+// call a tool that isn't registered on this request) - it gets its own
+// plain-text template instead, same as the HAR reverse-proxy fallback does
+func (h *PromptHandler) RewriteStream(c *gin.Context) {
+	var data struct {
+		Prompt string `json:"prompt"`
+	}
+	if err := c.ShouldBindJSON(&data); err != nil {
+		resp.ERROR(c, types.InvalidArgs)
+		return
+	}
+	h.streamPrompt(c, data.Prompt, rewriteFallbackPromptTemplate)
+}
+
+// TranslateStream is the SSE variant of Translate
+func (h *PromptHandler) TranslateStream(c *gin.Context) {
+	var data struct {
+		Prompt string `json:"prompt"`
+	}
+	if err := c.ShouldBindJSON(&data); err != nil {
+		resp.ERROR(c, types.InvalidArgs)
+		return
+	}
+	h.streamPrompt(c, data.Prompt, translatePromptTemplate)
+}
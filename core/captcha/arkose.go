@@ -0,0 +1,44 @@
+package captcha
+
+import (
+	"fmt"
+
+	"github.com/imroc/req/v3"
+)
+
+const arkoseChallengeURLTemplate = "https://client-api.arkoselabs.com/fc/gt2/public_key/%s"
+
+// arkoseTokenResponse is the relevant subset of arkoselabs' gt2 response
+type arkoseTokenResponse struct {
+	Token string `json:"token"`
+}
+
+// SolveArkose runs the acheong08/funcaptcha technique: post a "bda" blob
+// (the browser-fingerprint payload openai's web client would normally send)
+// to arkoselabs' public gt2 endpoint and return the resulting session token,
+// which callers forward upstream as the `openai-sentinel-arkose-token` header.
+func SolveArkose(publicKey string, bda string, proxyURL string) (string, error) {
+	url := fmt.Sprintf(arkoseChallengeURLTemplate, publicKey)
+
+	var res arkoseTokenResponse
+	r, err := req.C().SetProxyURL(proxyURL).R().
+		SetFormData(map[string]string{
+			"bda":          bda,
+			"public_key":   publicKey,
+			"site":         "https://chatgpt.com",
+			"userbrowser":  "Mozilla/5.0",
+			"capi_version": "2.5.0",
+			"capi_mode":    "inline",
+			"style_theme":  "default",
+			"rnd":          "0.1",
+		}).
+		SetSuccessResult(&res).
+		Post(url)
+	if err != nil || r.IsErrorState() {
+		return "", fmt.Errorf("error with solve arkose challenge: %v", err)
+	}
+	if res.Token == "" {
+		return "", fmt.Errorf("empty arkose token in response")
+	}
+	return res.Token, nil
+}
@@ -0,0 +1,47 @@
+// Package captcha verifies human-interaction challenges (Cloudflare
+// Turnstile, hCaptcha) and solves the OpenAI-style arkose funcaptcha
+// challenge used by the ChatGPT web reverse proxy, gating abuse-prone
+// endpoints like login and the prompt rewrite/translate handlers.
+package captcha
+
+import "fmt"
+
+// Config mirrors Config.Captcha in the application config file
+type Config struct {
+	Provider  string // "turnstile" | "hcaptcha" | "" (disabled)
+	SiteKey   string
+	SecretKey string
+}
+
+// Verifier checks a captcha response token submitted by the client
+type Verifier interface {
+	Verify(token string, remoteIP string) (bool, error)
+}
+
+// New builds the configured Verifier, returning nil when captcha is disabled
+func New(cfg Config) Verifier {
+	switch cfg.Provider {
+	case "turnstile":
+		return &turnstileVerifier{secretKey: cfg.SecretKey}
+	case "hcaptcha":
+		return &hCaptchaVerifier{secretKey: cfg.SecretKey}
+	default:
+		return nil
+	}
+}
+
+// siteVerifyResponse is the common response shape of both Turnstile and hCaptcha's siteverify endpoint
+type siteVerifyResponse struct {
+	Success    bool     `json:"success"`
+	ErrorCodes []string `json:"error-codes"`
+}
+
+func (r siteVerifyResponse) asResult() (bool, error) {
+	if r.Success {
+		return true, nil
+	}
+	if len(r.ErrorCodes) > 0 {
+		return false, fmt.Errorf("captcha verify failed: %v", r.ErrorCodes)
+	}
+	return false, nil
+}
@@ -0,0 +1,30 @@
+package captcha
+
+import (
+	"fmt"
+
+	"github.com/imroc/req/v3"
+)
+
+const hCaptchaVerifyURL = "https://hcaptcha.com/siteverify"
+
+type hCaptchaVerifier struct {
+	secretKey string
+}
+
+func (v *hCaptchaVerifier) Verify(token string, remoteIP string) (bool, error) {
+	if token == "" {
+		return false, nil
+	}
+
+	var res siteVerifyResponse
+	r, err := req.C().R().SetFormData(map[string]string{
+		"secret":   v.secretKey,
+		"response": token,
+		"remoteip": remoteIP,
+	}).SetSuccessResult(&res).Post(hCaptchaVerifyURL)
+	if err != nil || r.IsErrorState() {
+		return false, fmt.Errorf("error with verify hcaptcha token: %v", err)
+	}
+	return res.asResult()
+}
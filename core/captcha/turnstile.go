@@ -0,0 +1,30 @@
+package captcha
+
+import (
+	"fmt"
+
+	"github.com/imroc/req/v3"
+)
+
+const turnstileVerifyURL = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+
+type turnstileVerifier struct {
+	secretKey string
+}
+
+func (v *turnstileVerifier) Verify(token string, remoteIP string) (bool, error) {
+	if token == "" {
+		return false, nil
+	}
+
+	var res siteVerifyResponse
+	r, err := req.C().R().SetFormData(map[string]string{
+		"secret":   v.secretKey,
+		"response": token,
+		"remoteip": remoteIP,
+	}).SetSuccessResult(&res).Post(turnstileVerifyURL)
+	if err != nil || r.IsErrorState() {
+		return false, fmt.Errorf("error with verify turnstile token: %v", err)
+	}
+	return res.asResult()
+}
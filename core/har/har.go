@@ -0,0 +1,216 @@
+// Package har parses ChatGPT-Web .har exports and rotates them to provide
+// the sentinel headers required by https://chatgpt.com/backend-api/conversation,
+// acting as a fallback channel when no usable OpenAI API key is configured.
+package har
+
+import (
+	"chatplus/store/model"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	headerChatRequirementsToken = "openai-sentinel-chat-requirements-token"
+	headerTurnstileToken        = "openai-sentinel-turnstile"
+	headerProofToken            = "openai-sentinel-proof-token"
+	headerDeviceId              = "oai-device-id"
+
+	// arkoseChallengeURLPrefix 标记一条 HAR 请求是浏览器向 arkoselabs 发起的 funcaptcha
+	// 挑战，形如 https://client-api.arkoselabs.com/fc/gt2/public_key/{public_key}
+	arkoseChallengeURLPrefix = "https://client-api.arkoselabs.com/fc/gt2/public_key/"
+
+	// coolDownSeconds 是某个 HAR 在遇到 401/429 之后被跳过的时长
+	coolDownSeconds = 300
+	// maxErrorCount 连续失败达到该次数后直接禁用该 HAR，需要管理员手动处理
+	maxErrorCount = 5
+)
+
+// HarContext 是从一个 .har 条目中提取出来的、调用逆向代理接口所需的全部凭据
+type HarContext struct {
+	FileId                uint
+	ChatRequirementsToken string
+	TurnstileToken        string
+	ProofToken            string
+	DeviceId              string
+	ArkoseChallengeURL    string // arkose 挑战地址，形如 client-api.arkoselabs.com/fc/gt2/public_key/{pk}
+	ArkosePublicKey       string // 从 ArkoseChallengeURL 中截取出来的 public_key
+	ArkoseBda             string // 浏览器提交给 arkose 挑战接口的 "bda" 指纹 blob，喂给 SolveArkose 换取 token
+}
+
+// harEntry 记录 HAR 文件解析结果以及轮询调度所需的运行时状态
+type harEntry struct {
+	ctx        HarContext
+	enabled    bool
+	errorCount int
+	coolUntil  int64 // unix 秒，在此之前该条目不会被选中
+}
+
+// harFormParam is one entry of a HAR request's postData.params form fields
+type harFormParam struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// rawHar 对应 HAR 1.2 规范中我们关心的最小字段集合
+type rawHar struct {
+	Log struct {
+		Entries []struct {
+			Request struct {
+				URL     string `json:"url"`
+				Headers []struct {
+					Name  string `json:"name"`
+					Value string `json:"value"`
+				} `json:"headers"`
+				PostData struct {
+					Params []harFormParam `json:"params"`
+				} `json:"postData"`
+			} `json:"request"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+// arkosePublicKey 从挑战地址里截取出 public_key 部分，忽略浏览器可能附带的查询参数
+func arkosePublicKey(challengeURL string) string {
+	key := strings.TrimPrefix(challengeURL, arkoseChallengeURLPrefix)
+	if i := strings.IndexAny(key, "?/"); i >= 0 {
+		key = key[:i]
+	}
+	return key
+}
+
+// arkoseBda 在一条请求的 postData.params 里找浏览器提交的 "bda" 指纹字段
+func arkoseBda(params []harFormParam) string {
+	for _, p := range params {
+		if p.Name == "bda" {
+			return p.Value
+		}
+	}
+	return ""
+}
+
+// Parse 从一份 .har 文件全部请求条目的请求头中提取调用 /backend-api/conversation 所需的哨兵请求头
+// （不按 URL 过滤，同名请求头以最后出现的为准），并记录 arkose 挑战参数
+func Parse(fileId uint, content string) (HarContext, error) {
+	var doc rawHar
+	if err := json.Unmarshal([]byte(content), &doc); err != nil {
+		return HarContext{}, fmt.Errorf("error with parsing har file: %v", err)
+	}
+
+	ctx := HarContext{FileId: fileId}
+	for _, entry := range doc.Log.Entries {
+		for _, h := range entry.Request.Headers {
+			switch h.Name {
+			case headerChatRequirementsToken:
+				ctx.ChatRequirementsToken = h.Value
+			case headerTurnstileToken:
+				ctx.TurnstileToken = h.Value
+			case headerProofToken:
+				ctx.ProofToken = h.Value
+			case headerDeviceId:
+				ctx.DeviceId = h.Value
+			}
+		}
+		if strings.HasPrefix(entry.Request.URL, arkoseChallengeURLPrefix) {
+			ctx.ArkoseChallengeURL = entry.Request.URL
+			ctx.ArkosePublicKey = arkosePublicKey(entry.Request.URL)
+			ctx.ArkoseBda = arkoseBda(entry.Request.PostData.Params)
+		}
+	}
+
+	if ctx.ChatRequirementsToken == "" && ctx.ProofToken == "" {
+		return HarContext{}, fmt.Errorf("no sentinel headers found in this har file")
+	}
+	return ctx, nil
+}
+
+// Pool 维护一组可用的 HarContext，并以轮询的方式分发给调用方，
+// 对返回 401/429 的条目进行冷却，避免被同一个失效的会话反复卡住
+type Pool struct {
+	mu      sync.Mutex
+	entries []*harEntry
+	cursor  int
+}
+
+func NewPool() *Pool {
+	return &Pool{entries: make([]*harEntry, 0)}
+}
+
+// Load 用数据库中的 HarFile 记录重建整个池子，替换掉旧的状态
+func (p *Pool) Load(files []model.HarFile) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entries := make([]*harEntry, 0, len(files))
+	for _, f := range files {
+		if !f.Enabled {
+			continue
+		}
+		ctx, err := Parse(f.Id, f.RawContent)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, &harEntry{ctx: ctx, enabled: true})
+	}
+	p.entries = entries
+	p.cursor = 0
+}
+
+// NextHeaderSet 按照轮询顺序返回下一个未处于冷却状态的 HarContext
+func (p *Pool) NextHeaderSet() (HarContext, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.entries) == 0 {
+		return HarContext{}, fmt.Errorf("no har file available")
+	}
+
+	now := time.Now().Unix()
+	for i := 0; i < len(p.entries); i++ {
+		idx := (p.cursor + i) % len(p.entries)
+		e := p.entries[idx]
+		if !e.enabled || e.coolUntil > now {
+			continue
+		}
+		p.cursor = (idx + 1) % len(p.entries)
+		return e.ctx, nil
+	}
+
+	return HarContext{}, fmt.Errorf("all har files are cooling down or disabled")
+}
+
+// MarkFailure 记录某个 HarContext 调用失败，statusCode 为 401/429 时触发冷却，
+// 连续失败达到 maxErrorCount 次后直接禁用，等待管理员重新上传
+func (p *Pool) MarkFailure(fileId uint, statusCode int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, e := range p.entries {
+		if e.ctx.FileId != fileId {
+			continue
+		}
+		if statusCode == 401 || statusCode == 429 {
+			e.coolUntil = time.Now().Unix() + coolDownSeconds
+		}
+		e.errorCount++
+		if e.errorCount >= maxErrorCount {
+			e.enabled = false
+		}
+		return
+	}
+}
+
+// MarkSuccess 清除某个 HarContext 的失败计数
+func (p *Pool) MarkSuccess(fileId uint) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, e := range p.entries {
+		if e.ctx.FileId == fileId {
+			e.errorCount = 0
+			return
+		}
+	}
+}
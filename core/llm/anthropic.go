@@ -0,0 +1,129 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/imroc/req/v3"
+)
+
+const anthropicVersion = "2023-06-01"
+
+// AnthropicProvider talks to Anthropic's Messages API
+type AnthropicProvider struct {
+	apiURL   string // usually https://api.anthropic.com/v1/messages
+	apiKey   string
+	proxyURL string
+	model    string // e.g. claude-3-5-sonnet-20241022, never OpenAI's gpt-* ids
+}
+
+func NewAnthropicProvider(apiURL string, apiKey string, proxyURL string, model string) *AnthropicProvider {
+	return &AnthropicProvider{apiURL: apiURL, apiKey: apiKey, proxyURL: proxyURL, model: model}
+}
+
+func (p *AnthropicProvider) Name() string {
+	return "claude"
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicReq struct {
+	Model     string             `json:"model"`
+	MaxTokens int                `json:"max_tokens"`
+	Messages  []anthropicMessage `json:"messages"`
+	Stream    bool               `json:"stream"`
+}
+
+type anthropicRes struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	StopReason string `json:"stop_reason"`
+	Error      struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (p *AnthropicProvider) Chat(_ context.Context, chatReq ChatRequest) (ChatResponse, error) {
+	body := anthropicReq{
+		Model:     p.model,
+		MaxTokens: chatReq.MaxTokens,
+		Messages:  toAnthropicMessages(chatReq),
+		Stream:    false,
+	}
+
+	var response anthropicRes
+	r, err := req.C().SetProxyURL(p.proxyURL).R().SetHeader("Content-Type", "application/json").
+		SetHeader("x-api-key", p.apiKey).
+		SetHeader("anthropic-version", anthropicVersion).
+		SetBody(body).
+		SetSuccessResult(&response).Post(p.apiURL)
+	if err != nil || r.IsErrorState() {
+		return ChatResponse{}, fmt.Errorf("error with http request: %v%v%s", err, r.Err, response.Error.Message)
+	}
+	if len(response.Content) == 0 {
+		return ChatResponse{}, fmt.Errorf("empty content in response")
+	}
+
+	return ChatResponse{Content: response.Content[0].Text, FinishReason: response.StopReason}, nil
+}
+
+// Stream runs the request in its own goroutine: Post() blocks until the SSE
+// session ends, so doing it inline would keep the channel from reaching the
+// caller until after the stream was already over.
+func (p *AnthropicProvider) Stream(_ context.Context, chatReq ChatRequest) (<-chan Delta, error) {
+	body := anthropicReq{
+		Model:     p.model,
+		MaxTokens: chatReq.MaxTokens,
+		Messages:  toAnthropicMessages(chatReq),
+		Stream:    true,
+	}
+
+	ch := make(chan Delta)
+	go func() {
+		defer close(ch)
+		r, err := req.C().SetProxyURL(p.proxyURL).R().SetHeader("Content-Type", "application/json").
+			SetHeader("x-api-key", p.apiKey).
+			SetHeader("anthropic-version", anthropicVersion).
+			SetBody(body).
+			SetSSEHandler(func(event *req.Event) {
+				if event.Event == "content_block_delta" {
+					delta, _ := parseAnthropicDelta(event.Data)
+					ch <- delta
+				}
+			}).
+			Post(p.apiURL)
+		if err != nil || r.IsErrorState() {
+			ch <- Delta{Err: fmt.Errorf("error with http request: %v", err)}
+		}
+	}()
+	return ch, nil
+}
+
+// anthropicDeltaEvent is the payload of a `content_block_delta` SSE event
+type anthropicDeltaEvent struct {
+	Delta struct {
+		Text string `json:"text"`
+	} `json:"delta"`
+}
+
+func parseAnthropicDelta(data string) (Delta, error) {
+	var event anthropicDeltaEvent
+	if err := json.Unmarshal([]byte(data), &event); err != nil {
+		return Delta{Err: err}, err
+	}
+	return Delta{Content: event.Delta.Text}, nil
+}
+
+func toAnthropicMessages(chatReq ChatRequest) []anthropicMessage {
+	messages := make([]anthropicMessage, 0, len(chatReq.Messages))
+	for _, m := range chatReq.Messages {
+		messages = append(messages, anthropicMessage{Role: m.Role, Content: m.Content})
+	}
+	return messages
+}
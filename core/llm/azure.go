@@ -0,0 +1,85 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/imroc/req/v3"
+)
+
+// AzureProvider talks to an Azure OpenAI deployment. The wire format for the
+// request/response body is identical to OpenAI's, only the URL shape and the
+// auth header differ, so it builds the body with chatCompletionsBody and
+// decodes with the shared apiRes/parseSSEDelta, but sends its own request
+// with an `api-key` header instead of a Bearer Authorization header.
+type AzureProvider struct {
+	// endpoint is the full deployment URL including the api-version query string, e.g.
+	// https://{resource}.openai.azure.com/openai/deployments/{deployment}/chat/completions?api-version=2023-05-15
+	endpoint string
+	apiKey   string
+	proxyURL string
+	model    string
+}
+
+func NewAzureProvider(endpoint string, apiKey string, proxyURL string, model string) *AzureProvider {
+	return &AzureProvider{endpoint: endpoint, apiKey: apiKey, proxyURL: proxyURL, model: model}
+}
+
+func (p *AzureProvider) Name() string {
+	return "azure"
+}
+
+func (p *AzureProvider) Chat(_ context.Context, chatReq ChatRequest) (ChatResponse, error) {
+	chatReq.Model = p.model
+	var response apiRes
+	var errRes apiErrRes
+	r, err := req.C().SetProxyURL(p.proxyURL).R().SetHeader("Content-Type", "application/json").
+		SetHeader("api-key", p.apiKey).
+		SetBody(chatCompletionsBody(chatReq, false)).
+		SetErrorResult(&errRes).
+		SetSuccessResult(&response).Post(p.endpoint)
+	if err != nil || r.IsErrorState() {
+		return ChatResponse{}, fmt.Errorf("error with http request: %v%v%s", err, r.Err, errRes.Error.Message)
+	}
+	if len(response.Choices) == 0 {
+		return ChatResponse{}, fmt.Errorf("empty choices in response")
+	}
+
+	choice := response.Choices[0]
+	toolCalls := make([]ToolCall, 0, len(choice.Message.ToolCalls))
+	for _, tc := range choice.Message.ToolCalls {
+		toolCalls = append(toolCalls, ToolCall{Id: tc.Id, Name: tc.Function.Name, Arguments: tc.Function.Arguments})
+	}
+
+	return ChatResponse{
+		Content:      choice.Message.Content,
+		FinishReason: choice.FinishReason,
+		ToolCalls:    toolCalls,
+	}, nil
+}
+
+// Stream runs the request in its own goroutine: Post() blocks until the SSE
+// session ends, so doing it inline would keep the channel from reaching the
+// caller until after the stream was already over.
+func (p *AzureProvider) Stream(_ context.Context, chatReq ChatRequest) (<-chan Delta, error) {
+	chatReq.Model = p.model
+	ch := make(chan Delta)
+	go func() {
+		defer close(ch)
+		r, err := req.C().SetProxyURL(p.proxyURL).R().SetHeader("Content-Type", "application/json").
+			SetHeader("api-key", p.apiKey).
+			SetBody(chatCompletionsBody(chatReq, true)).
+			SetSSEHandler(func(event *req.Event) {
+				delta, done := parseSSEDelta(event.Data)
+				if done {
+					return
+				}
+				ch <- delta
+			}).
+			Post(p.endpoint)
+		if err != nil || r.IsErrorState() {
+			ch <- Delta{Err: fmt.Errorf("error with http request: %v", err)}
+		}
+	}()
+	return ch, nil
+}
@@ -0,0 +1,48 @@
+package llm
+
+import (
+	"chatplus/store/model"
+
+	"gorm.io/gorm"
+)
+
+// LoadRegistry builds a Registry from the enabled rows in chatgpt_llm_provider,
+// constructing and registering the concrete Provider implementation that
+// matches each row's Platform. This is the one place that actually calls
+// Registry.Register for each of the five supported platforms; callers wire it
+// into the app by invoking it once at startup with the shared DB handle.
+func LoadRegistry(db *gorm.DB, proxyURL string) (*Registry, error) {
+	var providers []model.LlmProvider
+	if res := db.Where("enabled = ?", true).Find(&providers); res.Error != nil {
+		return nil, res.Error
+	}
+
+	registry := NewRegistry()
+	for _, p := range providers {
+		provider := newProvider(p, proxyURL)
+		if provider == nil {
+			continue
+		}
+		registry.Register(provider)
+	}
+	return registry, nil
+}
+
+// newProvider constructs the Provider implementation for a single
+// chatgpt_llm_provider row, returning nil for an unrecognized platform
+func newProvider(p model.LlmProvider, proxyURL string) Provider {
+	switch p.Platform {
+	case "openai":
+		return NewOpenAIProvider(p.ApiURL, p.Secret, proxyURL, p.Model)
+	case "azure":
+		return NewAzureProvider(p.ApiURL, p.Secret, proxyURL, p.Model)
+	case "claude":
+		return NewAnthropicProvider(p.ApiURL, p.Secret, proxyURL, p.Model)
+	case "duckduckgo":
+		return NewDuckDuckGoProvider(proxyURL, p.Model)
+	case "compatible":
+		return NewCompatibleProvider(p.Name, p.ApiURL, p.Secret, proxyURL, p.Model)
+	default:
+		return nil
+	}
+}
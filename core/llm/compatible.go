@@ -0,0 +1,34 @@
+package llm
+
+import "context"
+
+// CompatibleProvider targets any self-hosted endpoint that speaks the OpenAI
+// chat-completions wire format (llama.cpp's server, Ollama's OpenAI-compat
+// API, DeepSeek's API, etc). name is the Name the admin gave the
+// model.LlmProvider row whose Platform is "compatible", e.g. "ollama",
+// "llama.cpp", "deepseek"; it is also the key the provider is registered under.
+type CompatibleProvider struct {
+	name     string
+	apiURL   string
+	apiKey   string
+	proxyURL string
+	model    string
+}
+
+func NewCompatibleProvider(name string, apiURL string, apiKey string, proxyURL string, model string) *CompatibleProvider {
+	return &CompatibleProvider{name: name, apiURL: apiURL, apiKey: apiKey, proxyURL: proxyURL, model: model}
+}
+
+func (p *CompatibleProvider) Name() string {
+	return p.name
+}
+
+func (p *CompatibleProvider) Chat(_ context.Context, req ChatRequest) (ChatResponse, error) {
+	req.Model = p.model
+	return openAICompatibleChat(p.apiURL, p.apiKey, p.proxyURL, req)
+}
+
+func (p *CompatibleProvider) Stream(_ context.Context, req ChatRequest) (<-chan Delta, error) {
+	req.Model = p.model
+	return openAICompatibleStream(p.apiURL, p.apiKey, p.proxyURL, req)
+}
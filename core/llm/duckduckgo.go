@@ -0,0 +1,138 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/imroc/req/v3"
+)
+
+const (
+	duckduckgoStatusURL = "https://duckduckgo.com/duckchat/v1/status"
+	duckduckgoChatURL   = "https://duckduckgo.com/duckchat/v1/chat"
+)
+
+// DuckDuckGoProvider mirrors the approach taken by the aurora/duck2api
+// project: DuckDuckGo-Chat requires no API key, just a short-lived `x-vqd-4`
+// token minted per conversation via a GET to the status endpoint.
+type DuckDuckGoProvider struct {
+	proxyURL string
+	model    string // one of duckduckgo's supported model ids, e.g. "gpt-4o-mini"
+}
+
+func NewDuckDuckGoProvider(proxyURL string, model string) *DuckDuckGoProvider {
+	return &DuckDuckGoProvider{proxyURL: proxyURL, model: model}
+}
+
+func (p *DuckDuckGoProvider) Name() string {
+	return "duckduckgo"
+}
+
+type duckduckgoMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type duckduckgoReq struct {
+	Model    string              `json:"model"`
+	Messages []duckduckgoMessage `json:"messages"`
+}
+
+// fetchVqdToken retrieves the rolling `x-vqd-4` header required by every chat call
+func (p *DuckDuckGoProvider) fetchVqdToken() (string, error) {
+	r, err := req.C().SetProxyURL(p.proxyURL).R().
+		SetHeader("x-vqd-accept", "1").
+		Get(duckduckgoStatusURL)
+	if err != nil || r.IsErrorState() {
+		return "", fmt.Errorf("error with fetch vqd token: %v", err)
+	}
+
+	token := r.Header.Get("x-vqd-4")
+	if token == "" {
+		return "", fmt.Errorf("empty vqd token")
+	}
+	return token, nil
+}
+
+func toDuckDuckGoMessages(chatReq ChatRequest) []duckduckgoMessage {
+	messages := make([]duckduckgoMessage, 0, len(chatReq.Messages))
+	for _, m := range chatReq.Messages {
+		messages = append(messages, duckduckgoMessage{Role: m.Role, Content: m.Content})
+	}
+	return messages
+}
+
+func (p *DuckDuckGoProvider) Chat(_ context.Context, chatReq ChatRequest) (ChatResponse, error) {
+	token, err := p.fetchVqdToken()
+	if err != nil {
+		return ChatResponse{}, err
+	}
+
+	var content string
+	r, err := req.C().SetProxyURL(p.proxyURL).R().
+		SetHeader("Content-Type", "application/json").
+		SetHeader("x-vqd-4", token).
+		SetBody(duckduckgoReq{Model: p.model, Messages: toDuckDuckGoMessages(chatReq)}).
+		SetSSEHandler(func(event *req.Event) {
+			delta, done := parseDuckDuckGoDelta(event.Data)
+			if !done {
+				content += delta.Content
+			}
+		}).
+		Post(duckduckgoChatURL)
+	if err != nil || r.IsErrorState() {
+		return ChatResponse{}, fmt.Errorf("error with http request: %v", err)
+	}
+	if content == "" {
+		return ChatResponse{}, fmt.Errorf("empty response from duckduckgo-chat")
+	}
+
+	return ChatResponse{Content: content}, nil
+}
+
+// Stream runs the request in its own goroutine: Post() blocks until the SSE
+// session ends, so doing it inline would keep the channel from reaching the
+// caller until after the stream was already over.
+func (p *DuckDuckGoProvider) Stream(_ context.Context, chatReq ChatRequest) (<-chan Delta, error) {
+	token, err := p.fetchVqdToken()
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan Delta)
+	go func() {
+		defer close(ch)
+		r, err := req.C().SetProxyURL(p.proxyURL).R().
+			SetHeader("Content-Type", "application/json").
+			SetHeader("x-vqd-4", token).
+			SetBody(duckduckgoReq{Model: p.model, Messages: toDuckDuckGoMessages(chatReq)}).
+			SetSSEHandler(func(event *req.Event) {
+				delta, done := parseDuckDuckGoDelta(event.Data)
+				if done {
+					return
+				}
+				ch <- delta
+			}).
+			Post(duckduckgoChatURL)
+		if err != nil || r.IsErrorState() {
+			ch <- Delta{Err: fmt.Errorf("error with http request: %v", err)}
+		}
+	}()
+	return ch, nil
+}
+
+type duckduckgoChunk struct {
+	Message string `json:"message"`
+}
+
+func parseDuckDuckGoDelta(data string) (Delta, bool) {
+	if data == "[DONE]" {
+		return Delta{}, true
+	}
+	var chunk duckduckgoChunk
+	if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+		return Delta{Err: err}, false
+	}
+	return Delta{Content: chunk.Message}, false
+}
@@ -0,0 +1,66 @@
+// Package llm abstracts away the differences between chat-completion providers
+// (OpenAI, Azure OpenAI, DuckDuckGo-Chat, Anthropic Claude, and OpenAI-compatible
+// self-hosted endpoints) so callers can dispatch through a single interface.
+package llm
+
+import (
+	"chatplus/core/types"
+	"context"
+)
+
+// ChatRequest is the provider-agnostic request shape built from the
+// application's own types.Message list
+type ChatRequest struct {
+	Model       string
+	Messages    []types.Message
+	Temperature float32
+	MaxTokens   int
+	// Tools/ToolChoice carry an OpenAI-style function-calling schema. Only the
+	// OpenAI-wire-compatible providers (OpenAI, Azure, Compatible) honor them.
+	Tools      []ToolDef
+	ToolChoice interface{}
+}
+
+// ToolDef is a single OpenAI-style function tool definition
+type ToolDef struct {
+	Type     string          `json:"type"`
+	Function ToolFunctionDef `json:"function"`
+}
+
+type ToolFunctionDef struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Parameters  map[string]interface{} `json:"parameters"`
+}
+
+// ToolCall is one function call the model chose to make, as returned in the
+// assistant message's tool_calls
+type ToolCall struct {
+	Id        string
+	Name      string
+	Arguments string // raw JSON string of arguments, caller unmarshal into its own struct
+}
+
+// ChatResponse is the provider-agnostic result of a non-streaming Chat call
+type ChatResponse struct {
+	Content      string
+	FinishReason string
+	ToolCalls    []ToolCall
+}
+
+// Delta is a single token/fragment emitted while streaming
+type Delta struct {
+	Content      string
+	FinishReason string
+	Err          error
+}
+
+// Provider is implemented by every concrete backend (OpenAI, Azure, Claude, ...)
+type Provider interface {
+	// Name returns the platform identifier used to look the provider up in the registry,
+	// e.g. "openai", "azure", "claude", "duckduckgo"; a CompatibleProvider returns
+	// the Name of its model.LlmProvider row
+	Name() string
+	Chat(ctx context.Context, req ChatRequest) (ChatResponse, error)
+	Stream(ctx context.Context, req ChatRequest) (<-chan Delta, error)
+}
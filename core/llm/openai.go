@@ -0,0 +1,111 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/imroc/req/v3"
+)
+
+// OpenAIProvider talks to the standard OpenAI chat-completions endpoint
+type OpenAIProvider struct {
+	apiURL   string
+	apiKey   string
+	proxyURL string
+	model    string
+}
+
+func NewOpenAIProvider(apiURL string, apiKey string, proxyURL string, model string) *OpenAIProvider {
+	return &OpenAIProvider{apiURL: apiURL, apiKey: apiKey, proxyURL: proxyURL, model: model}
+}
+
+func (p *OpenAIProvider) Name() string {
+	return "openai"
+}
+
+func (p *OpenAIProvider) Chat(_ context.Context, req ChatRequest) (ChatResponse, error) {
+	req.Model = p.model
+	return openAICompatibleChat(p.apiURL, p.apiKey, p.proxyURL, req)
+}
+
+func (p *OpenAIProvider) Stream(_ context.Context, req ChatRequest) (<-chan Delta, error) {
+	req.Model = p.model
+	return openAICompatibleStream(p.apiURL, p.apiKey, p.proxyURL, req)
+}
+
+// chatCompletionsBody builds the raw OpenAI chat-completions request body,
+// including the function-calling fields when the caller set them
+func chatCompletionsBody(chatReq ChatRequest, stream bool) map[string]interface{} {
+	body := map[string]interface{}{
+		"model":       chatReq.Model,
+		"temperature": chatReq.Temperature,
+		"max_tokens":  chatReq.MaxTokens,
+		"stream":      stream,
+		"messages":    chatReq.Messages,
+	}
+	if len(chatReq.Tools) > 0 {
+		body["tools"] = chatReq.Tools
+	}
+	if chatReq.ToolChoice != nil {
+		body["tool_choice"] = chatReq.ToolChoice
+	}
+	return body
+}
+
+// openAICompatibleChat implements the OpenAI chat-completions wire format,
+// shared by OpenAIProvider and CompatibleProvider (AzureProvider sends its own
+// request because it authenticates with an api-key header)
+func openAICompatibleChat(apiURL string, apiKey string, proxyURL string, chatReq ChatRequest) (ChatResponse, error) {
+	var response apiRes
+	var errRes apiErrRes
+	r, err := req.C().SetProxyURL(proxyURL).R().SetHeader("Content-Type", "application/json").
+		SetHeader("Authorization", "Bearer "+apiKey).
+		SetBody(chatCompletionsBody(chatReq, false)).
+		SetErrorResult(&errRes).
+		SetSuccessResult(&response).Post(apiURL)
+	if err != nil || r.IsErrorState() {
+		return ChatResponse{}, fmt.Errorf("error with http request: %v%v%s", err, r.Err, errRes.Error.Message)
+	}
+	if len(response.Choices) == 0 {
+		return ChatResponse{}, fmt.Errorf("empty choices in response")
+	}
+
+	choice := response.Choices[0]
+	toolCalls := make([]ToolCall, 0, len(choice.Message.ToolCalls))
+	for _, tc := range choice.Message.ToolCalls {
+		toolCalls = append(toolCalls, ToolCall{Id: tc.Id, Name: tc.Function.Name, Arguments: tc.Function.Arguments})
+	}
+
+	return ChatResponse{
+		Content:      choice.Message.Content,
+		FinishReason: choice.FinishReason,
+		ToolCalls:    toolCalls,
+	}, nil
+}
+
+// openAICompatibleStream issues the same request with Stream:true and forwards
+// each `data: {...}` SSE chunk as a Delta. Post() blocks for the whole SSE
+// session, so it runs in its own goroutine and the channel is handed back to
+// the caller immediately instead of only after the stream has ended.
+func openAICompatibleStream(apiURL string, apiKey string, proxyURL string, chatReq ChatRequest) (<-chan Delta, error) {
+	ch := make(chan Delta)
+	go func() {
+		defer close(ch)
+		r, err := req.C().SetProxyURL(proxyURL).R().SetHeader("Content-Type", "application/json").
+			SetHeader("Authorization", "Bearer "+apiKey).
+			SetBody(chatCompletionsBody(chatReq, true)).
+			SetSSEHandler(func(event *req.Event) {
+				delta, done := parseSSEDelta(event.Data)
+				if done {
+					return
+				}
+				ch <- delta
+			}).
+			Post(apiURL)
+		if err != nil || r.IsErrorState() {
+			ch <- Delta{Err: fmt.Errorf("error with http request: %v", err)}
+		}
+	}()
+
+	return ch, nil
+}
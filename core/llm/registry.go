@@ -0,0 +1,46 @@
+package llm
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Registry is the app-wide lookup table from platform name to a configured Provider.
+// Handlers resolve the provider they need by name instead of hard-coding a backend.
+type Registry struct {
+	mu        sync.RWMutex
+	providers map[string]Provider
+}
+
+func NewRegistry() *Registry {
+	return &Registry{providers: make(map[string]Provider)}
+}
+
+// Register adds or replaces the provider for a given platform name
+func (r *Registry) Register(p Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[p.Name()] = p
+}
+
+// Get looks up a provider by platform name
+func (r *Registry) Get(name string) (Provider, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("no llm provider registered for platform: %s", name)
+	}
+	return p, nil
+}
+
+// All returns every currently registered provider
+func (r *Registry) All() []Provider {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := make([]Provider, 0, len(r.providers))
+	for _, p := range r.providers {
+		list = append(list, p)
+	}
+	return list
+}
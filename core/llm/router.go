@@ -0,0 +1,200 @@
+package llm
+
+import (
+	"chatplus/store/model"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// unhealthyCoolDown 是某个 ApiKey 调用失败一次（任何错误，包括 5xx/429）之后被路由跳过的时长
+const unhealthyCoolDown = 10 * time.Minute
+
+// Router implements "model-router" dispatch: try the cheapest healthy
+// provider first, and fall back to the next one when a call fails with any
+// error (5xx/429 included), recording the
+// failure against the ApiKeyAccessStat row so a broken key is skipped for a
+// while instead of being retried on every single request.
+type Router struct {
+	db       *gorm.DB
+	registry *Registry
+}
+
+func NewRouter(db *gorm.DB, registry *Registry) *Router {
+	return &Router{db: db, registry: registry}
+}
+
+// candidate pairs a provider with the ApiKey row used to reach it, so a
+// failure can be attributed back to the right access-stat record
+type candidate struct {
+	apiKey   model.ApiKey
+	provider Provider
+}
+
+func (r *Router) candidates() ([]candidate, error) {
+	var keys []model.ApiKey
+	if res := r.db.Find(&keys); res.Error != nil {
+		return nil, res.Error
+	}
+
+	var providers []model.LlmProvider
+	if res := r.db.Where("enabled = ?", true).Order("price_rank asc").Find(&providers); res.Error != nil {
+		return nil, res.Error
+	}
+	rank := make(map[string]int, len(providers))
+	for _, p := range providers {
+		key := p.Platform
+		if p.Platform == "compatible" {
+			// CompatibleProvider is registered under the row's Name, see newProvider
+			key = p.Name
+		}
+		rank[key] = p.PriceRank
+	}
+
+	now := time.Now().Unix()
+	var stats []model.ApiKeyAccessStat
+	if res := r.db.Find(&stats); res.Error != nil {
+		return nil, res.Error
+	}
+	disabledUntil := make(map[uint]int64, len(stats))
+	for _, s := range stats {
+		disabledUntil[s.ApiKeyId] = s.DisabledUntil
+	}
+
+	list := make([]candidate, 0, len(keys))
+	for _, k := range keys {
+		if until, ok := disabledUntil[k.Id]; ok && until > now {
+			continue
+		}
+		provider, err := r.registry.Get(k.Platform)
+		if err != nil {
+			continue
+		}
+		list = append(list, candidate{apiKey: k, provider: provider})
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return rank[list[i].apiKey.Platform] < rank[list[j].apiKey.Platform]
+	})
+	return list, nil
+}
+
+// Chat tries each healthy candidate, cheapest first, until one succeeds
+func (r *Router) Chat(ctx context.Context, req ChatRequest) (ChatResponse, error) {
+	candidates, err := r.candidates()
+	if err != nil {
+		return ChatResponse{}, fmt.Errorf("error with loading llm candidates: %v", err)
+	}
+	if len(candidates) == 0 {
+		return ChatResponse{}, fmt.Errorf("no healthy llm provider available")
+	}
+
+	var lastErr error
+	for _, c := range candidates {
+		res, err := c.provider.Chat(ctx, req)
+		if err == nil {
+			r.touch(c.apiKey.Id, false)
+			return res, nil
+		}
+		lastErr = err
+		r.touch(c.apiKey.Id, true)
+	}
+	return ChatResponse{}, fmt.Errorf("all llm providers failed, last error: %v", lastErr)
+}
+
+// Stream tries each healthy candidate, cheapest first, until one starts
+// streaming. Provider.Stream runs its HTTP request in a goroutine and hands
+// back an open channel before the request has actually been attempted, so a
+// nil error from it doesn't mean the candidate is healthy — Stream peeks at
+// the first Delta (blocking until the provider's goroutine produces one) to
+// detect a connection/auth failure and fall through to the next candidate,
+// then replays that first Delta onto the channel it returns.
+func (r *Router) Stream(ctx context.Context, req ChatRequest) (<-chan Delta, error) {
+	candidates, err := r.candidates()
+	if err != nil {
+		return nil, fmt.Errorf("error with loading llm candidates: %v", err)
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no healthy llm provider available")
+	}
+
+	var lastErr error
+	for _, c := range candidates {
+		ch, err := c.provider.Stream(ctx, req)
+		if err != nil {
+			lastErr = err
+			r.touch(c.apiKey.Id, true)
+			continue
+		}
+
+		first, ok := <-ch
+		if !ok {
+			// Closed with nothing sent isn't necessarily a failure: some
+			// providers (e.g. Anthropic only sends on content_block_delta)
+			// close an empty-but-successful completion the same way. Treat
+			// it as success rather than cooling down a healthy key.
+			r.touch(c.apiKey.Id, false)
+			empty := make(chan Delta)
+			close(empty)
+			return empty, nil
+		}
+		if first.Err != nil {
+			lastErr = first.Err
+			r.touch(c.apiKey.Id, true)
+			// ch is abandoned here, but the provider's SSE handler goroutine
+			// is still trying to send on it (a parse error doesn't stop the
+			// upstream stream) — drain it in the background so that send
+			// doesn't block forever and leak the goroutine/connection.
+			go drainDelta(ch)
+			continue
+		}
+
+		r.touch(c.apiKey.Id, false)
+		return prependDelta(first, ch), nil
+	}
+	return nil, fmt.Errorf("all llm providers failed, last error: %v", lastErr)
+}
+
+// drainDelta discards every remaining Delta from ch so an abandoned
+// provider stream's sender doesn't block forever with no reader left
+func drainDelta(ch <-chan Delta) {
+	for range ch {
+	}
+}
+
+// prependDelta returns a channel that yields first, then forwards every
+// remaining Delta from rest
+func prependDelta(first Delta, rest <-chan Delta) <-chan Delta {
+	out := make(chan Delta)
+	go func() {
+		defer close(out)
+		out <- first
+		for d := range rest {
+			out <- d
+		}
+	}()
+	return out
+}
+
+// touch updates the ApiKeyAccessStat row, putting the key into a cool-down
+// window after it fails so the next request skips straight to a healthy one
+func (r *Router) touch(apiKeyId uint, failed bool) {
+	var stat model.ApiKeyAccessStat
+	res := r.db.Where("api_key_id = ?", apiKeyId).FirstOrCreate(&stat, model.ApiKeyAccessStat{ApiKeyId: apiKeyId})
+	if res.Error != nil {
+		return
+	}
+
+	stat.LastAccessedAt = time.Now().Unix()
+	if failed {
+		stat.FailCount++
+		stat.DisabledUntil = time.Now().Add(unhealthyCoolDown).Unix()
+	} else {
+		stat.FailCount = 0
+		stat.DisabledUntil = 0
+	}
+	r.db.Save(&stat)
+}
@@ -0,0 +1,63 @@
+package llm
+
+import "encoding/json"
+
+// apiRes mirrors the OpenAI chat-completions response body, reused by every
+// OpenAI-wire-compatible provider (OpenAI, Azure, llama.cpp/Ollama/DeepSeek)
+type apiRes struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Index   int `json:"index"`
+		Message struct {
+			Role      string `json:"role"`
+			Content   string `json:"content"`
+			ToolCalls []struct {
+				Id       string `json:"id"`
+				Function struct {
+					Name      string `json:"name"`
+					Arguments string `json:"arguments"`
+				} `json:"function"`
+			} `json:"tool_calls"`
+		} `json:"message"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+}
+
+type apiErrRes struct {
+	Error struct {
+		Code    interface{} `json:"code"`
+		Message string      `json:"message"`
+		Param   interface{} `json:"param"`
+		Type    string      `json:"type"`
+	} `json:"error"`
+}
+
+// sseChunk is the `data: {...}` payload of a streaming chat-completions response
+type sseChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+}
+
+// parseSSEDelta turns one SSE data frame into a Delta, done=true once the
+// upstream sends the terminating "[DONE]" frame
+func parseSSEDelta(data string) (Delta, bool) {
+	if data == "[DONE]" {
+		return Delta{}, true
+	}
+
+	var chunk sseChunk
+	if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+		return Delta{Err: err}, false
+	}
+	if len(chunk.Choices) == 0 {
+		return Delta{}, false
+	}
+	return Delta{
+		Content:      chunk.Choices[0].Delta.Content,
+		FinishReason: chunk.Choices[0].FinishReason,
+	}, false
+}
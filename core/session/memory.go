@@ -0,0 +1,139 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+type memoryItem struct {
+	raw      []byte
+	username string
+	expireAt time.Time // zero value means no expiry
+}
+
+func (i memoryItem) expired() bool {
+	return !i.expireAt.IsZero() && time.Now().After(i.expireAt)
+}
+
+// MemoryStore is the single-process implementation, kept as the default so a
+// standalone deployment doesn't need a Redis instance
+type MemoryStore struct {
+	mu    sync.RWMutex
+	items map[string]*memoryItem
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{items: make(map[string]*memoryItem)}
+}
+
+func (s *MemoryStore) Get(key string, out interface{}) (bool, error) {
+	s.mu.RLock()
+	item, ok := s.items[key]
+	s.mu.RUnlock()
+	if !ok || item.expired() {
+		return false, nil
+	}
+	if err := json.Unmarshal(item.raw, out); err != nil {
+		return false, fmt.Errorf("error with unmarshal session value: %v", err)
+	}
+	return true, nil
+}
+
+func (s *MemoryStore) Put(key string, value interface{}, ttl time.Duration) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("error with marshal session value: %v", err)
+	}
+
+	item := &memoryItem{raw: raw}
+	if ttl > 0 {
+		item.expireAt = time.Now().Add(ttl)
+	}
+	if username, ok := extractUsername(value); ok {
+		item.username = username
+	}
+
+	s.mu.Lock()
+	s.items[key] = item
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemoryStore) Delete(key string) error {
+	s.mu.Lock()
+	delete(s.items, key)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemoryStore) Touch(key string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, ok := s.items[key]
+	if !ok || item.expired() {
+		return fmt.Errorf("session key not found: %s", key)
+	}
+	if ttl > 0 {
+		item.expireAt = time.Now().Add(ttl)
+	}
+	return nil
+}
+
+func (s *MemoryStore) ListByUser(username string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]string, 0)
+	for key, item := range s.items {
+		if item.username == username && !item.expired() {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (s *MemoryStore) Incr(key string, ttl time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.items[key]
+	if !ok || item.expired() {
+		item = &memoryItem{raw: []byte("1")}
+		if ttl > 0 {
+			item.expireAt = time.Now().Add(ttl)
+		}
+		s.items[key] = item
+		return 1, nil
+	}
+
+	var count int64
+	if err := json.Unmarshal(item.raw, &count); err != nil {
+		count = 0
+	}
+	count++
+	raw, _ := json.Marshal(count)
+	item.raw = raw
+	return count, nil
+}
+
+// extractUsername pulls the username out of value, from a GetUsername()
+// method or else an exported string field named "Username", so MemoryStore
+// can serve ListByUser without a separate index
+func extractUsername(value interface{}) (string, bool) {
+	type named interface {
+		GetUsername() string
+	}
+	if n, ok := value.(named); ok {
+		return n.GetUsername(), true
+	}
+	v := reflect.Indirect(reflect.ValueOf(value))
+	if v.Kind() == reflect.Struct {
+		if f := v.FieldByName("Username"); f.IsValid() && f.Kind() == reflect.String {
+			return f.String(), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,116 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore lets multiple chatplus replicas share login sessions, chat
+// context and rate-limit counters behind a load balancer
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRedisStore(addr string, password string, db int, prefix string) *RedisStore {
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: password,
+		DB:       db,
+	})
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+func (s *RedisStore) key(key string) string {
+	return s.prefix + key
+}
+
+// userIndexKey is the Redis SET that tracks which session keys belong to a given user
+func (s *RedisStore) userIndexKey(username string) string {
+	return s.prefix + "user-index:" + username
+}
+
+func (s *RedisStore) Get(key string, out interface{}) (bool, error) {
+	raw, err := s.client.Get(context.Background(), s.key(key)).Bytes()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error with get session key %s: %v", key, err)
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return false, fmt.Errorf("error with unmarshal session value: %v", err)
+	}
+	return true, nil
+}
+
+func (s *RedisStore) Put(key string, value interface{}, ttl time.Duration) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("error with marshal session value: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := s.client.Set(ctx, s.key(key), raw, ttl).Err(); err != nil {
+		return fmt.Errorf("error with put session key %s: %v", key, err)
+	}
+
+	if username, ok := extractUsername(value); ok {
+		if err := s.client.SAdd(ctx, s.userIndexKey(username), key).Err(); err != nil {
+			return fmt.Errorf("error with index session key %s: %v", key, err)
+		}
+	}
+	return nil
+}
+
+func (s *RedisStore) Delete(key string) error {
+	if err := s.client.Del(context.Background(), s.key(key)).Err(); err != nil {
+		return fmt.Errorf("error with delete session key %s: %v", key, err)
+	}
+	return nil
+}
+
+func (s *RedisStore) Touch(key string, ttl time.Duration) error {
+	if err := s.client.Expire(context.Background(), s.key(key), ttl).Err(); err != nil {
+		return fmt.Errorf("error with touch session key %s: %v", key, err)
+	}
+	return nil
+}
+
+func (s *RedisStore) ListByUser(username string) ([]string, error) {
+	ctx := context.Background()
+	keys, err := s.client.SMembers(ctx, s.userIndexKey(username)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("error with list session keys for user %s: %v", username, err)
+	}
+	// the index set is not pruned on expiry or Delete, so skip keys whose session is gone
+	live := make([]string, 0, len(keys))
+	for _, key := range keys {
+		n, err := s.client.Exists(ctx, s.key(key)).Result()
+		if err != nil {
+			return nil, fmt.Errorf("error with check session key %s: %v", key, err)
+		}
+		if n > 0 {
+			live = append(live, key)
+		}
+	}
+	return live, nil
+}
+
+// Incr mirrors the classic Redis rate-limit idiom: INCR then EXPIRE only on
+// the first hit of a fresh window, so the TTL isn't pushed back on every request
+func (s *RedisStore) Incr(key string, ttl time.Duration) (int64, error) {
+	ctx := context.Background()
+	count, err := s.client.Incr(ctx, s.key(key)).Result()
+	if err != nil {
+		return 0, fmt.Errorf("error with incr rate-limit key %s: %v", key, err)
+	}
+	if count == 1 && ttl > 0 {
+		s.client.Expire(ctx, s.key(key), ttl)
+	}
+	return count, nil
+}
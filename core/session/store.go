@@ -0,0 +1,25 @@
+// Package session abstracts the storage backing login sessions, chat
+// context and API-key rate-limit counters, so chatplus can run as more than
+// one replica behind a load balancer instead of keeping that state in
+// process-local maps.
+package session
+
+import "time"
+
+// Store is implemented by both the in-memory (single-process) backend and
+// the Redis-backed (multi-replica) backend
+type Store interface {
+	// Get unmarshals the value stored under key into out, returns ok=false if missing
+	Get(key string, out interface{}) (ok bool, err error)
+	// Put marshals value and stores it under key with the given ttl (0 means no expiry)
+	Put(key string, value interface{}, ttl time.Duration) error
+	Delete(key string) error
+	// Touch resets the ttl of an existing key without changing its value
+	Touch(key string, ttl time.Duration) error
+	// ListByUser returns every session key currently registered for username
+	ListByUser(username string) ([]string, error)
+	// Incr atomically increments the counter stored under key and (re)arms its
+	// ttl on first increment, mirroring Redis' INCR + EXPIRE pattern. Used for
+	// the 15 req/min API-key rate limit.
+	Incr(key string, ttl time.Duration) (int64, error)
+}
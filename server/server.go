@@ -1,11 +1,15 @@
 package server
 
 import (
+	"bytes"
+	"chatplus/core/captcha"
+	"chatplus/core/session"
 	"embed"
 	"encoding/json"
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-contrib/sessions/cookie"
 	"github.com/gin-gonic/gin"
+	"io"
 	"io/fs"
 	"net/http"
 	logger2 "openai/logger"
@@ -15,6 +19,7 @@ import (
 	"path/filepath"
 	"runtime/debug"
 	"strings"
+	"time"
 )
 
 var logger = logger2.GetLogger()
@@ -30,16 +35,23 @@ func (s StaticFile) Open(name string) (fs.File, error) {
 	return file, err
 }
 
+const (
+	chatSessionKeyPrefix = "chat-session:"
+	apiKeyStatKeyPrefix  = "apikey-stat:"
+	apiKeyRateLimitTTL   = time.Minute
+	apiKeyRateLimit      = 15 // 每个用户每分钟最多发起的聊天请求数
+)
+
 type Server struct {
-	Config      *types.Config
-	ConfigPath  string
-	ChatContext map[string][]types.Message // 聊天上下文 [SessionID] => []Messages
-
-	// 保存 Websocket 会话 Username, 每个 Username 只能连接一次
-	// 防止第三方直接连接 socket 调用 OpenAI API
-	ChatSession      map[string]types.ChatSession
-	ApiKeyAccessStat map[string]int64 // 记录每个 API Key 的最后访问之间，保持在 15/min 之内
-	DebugMode        bool             // 是否开启调试模式
+	Config     *types.Config
+	ConfigPath string
+
+	// Session 取代了之前进程内的 ChatSession / ApiKeyAccessStat 两个裸 map，
+	// 使多个 chatplus 副本可以共享登录态和限流计数。这个仓库里没有别处用到过
+	// 独立的 ChatContext 状态（聊天上下文本就是跟着 ChatSession 一起存取的），
+	// 所以没有第三个需要迁移的 map
+	Session   session.Store
+	DebugMode bool // 是否开启调试模式
 }
 
 func NewServer(configPath string) (*Server, error) {
@@ -59,14 +71,32 @@ func NewServer(configPath string) (*Server, error) {
 		}
 	}
 	return &Server{
-		Config:           config,
-		ConfigPath:       configPath,
-		ChatContext:      make(map[string][]types.Message, 16),
-		ChatSession:      make(map[string]types.ChatSession),
-		ApiKeyAccessStat: make(map[string]int64),
+		Config:     config,
+		ConfigPath: configPath,
+		Session:    newSessionStore(config),
 	}, nil
 }
 
+// newSessionStore picks the Redis-backed store when configured, falling back
+// to the single-process in-memory store for a standalone deployment
+func newSessionStore(config *types.Config) session.Store {
+	if config.Redis.Enabled {
+		return session.NewRedisStore(config.Redis.Addr, config.Redis.Password, config.Redis.DB, "chatplus:")
+	}
+	return session.NewMemoryStore()
+}
+
+// checkRateLimit 用 Session store 的 INCR+EXPIRE 实现跨副本一致的限流，取代原先
+// 进程内 ApiKeyAccessStat map 的 15 次/分钟限制
+func (s *Server) checkRateLimit(key string) bool {
+	count, err := s.Session.Incr(apiKeyStatKeyPrefix+key, apiKeyRateLimitTTL)
+	if err != nil {
+		logger.Error("Error for check rate limit: ", err)
+		return true
+	}
+	return count <= apiKeyRateLimit
+}
+
 func (s *Server) Run(webRoot embed.FS, path string, debug bool) {
 	s.DebugMode = debug
 	gin.SetMode(gin.ReleaseMode)
@@ -76,6 +106,7 @@ func (s *Server) Run(webRoot embed.FS, path string, debug bool) {
 	}
 	engine.Use(sessionMiddleware(s.Config))
 	engine.Use(AuthorizeMiddleware(s))
+	engine.Use(CaptchaMiddleware(s))
 	engine.Use(Recover)
 
 	engine.GET("/hello", Hello)
@@ -205,11 +236,18 @@ func AuthorizeMiddleware(s *Server) gin.HandlerFunc {
 		// WebSocket 连接请求验证
 		if c.Request.URL.Path == "/api/chat" {
 			sessionId := c.Query("sessionId")
-			if session, ok := s.ChatSession[sessionId]; ok && session.ClientIP == c.ClientIP() {
-				c.Next()
-			} else {
+			var chatSession types.ChatSession
+			ok, err := s.Session.Get(chatSessionKeyPrefix+sessionId, &chatSession)
+			if err != nil || !ok || chatSession.ClientIP != c.ClientIP() {
+				c.Abort()
+				return
+			}
+			if !s.checkRateLimit(chatSession.Username) {
 				c.Abort()
+				c.JSON(http.StatusOK, types.BizVo{Code: types.Failed, Message: "Too Many Requests"})
+				return
 			}
+			c.Next()
 			return
 		}
 
@@ -229,9 +267,65 @@ func AuthorizeMiddleware(s *Server) gin.HandlerFunc {
 	}
 }
 
+// captchaGuardedPaths 是需要人机验证的接口，管理员的 /api/config/* 接口不在此列。
+// /api/chat 是 WebSocket 握手（GET + sessionId 查询参数，没有 JSON body），它复用的
+// 是 /api/login 时已经校验过一次的会话，因此不在此列，否则每次握手都会被当成一次
+// 空 captcha_token 校验而直接拒绝。
+var captchaGuardedPaths = map[string]bool{
+	"/api/login":            true,
+	"/api/prompt/rewrite":   true,
+	"/api/prompt/translate": true,
+}
+
+// CaptchaMiddleware 在登录以及 Prompt 改写/翻译等容易被滥用的接口前加一道
+// Turnstile / hCaptcha 人机验证，避免被脚本批量调用
+func CaptchaMiddleware(s *Server) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s.Config.Captcha.Provider == "" || !captchaGuardedPaths[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+
+		raw, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusOK, types.BizVo{Code: types.Failed, Message: types.ErrorMsg})
+			c.Abort()
+			return
+		}
+		// 读取完之后把 body 还回去，后面的 handler 还要再解析一遍
+		c.Request.Body = io.NopCloser(bytes.NewReader(raw))
+
+		var data struct {
+			CaptchaToken string `json:"captcha_token"`
+		}
+		_ = json.Unmarshal(raw, &data)
+
+		verifier := captcha.New(captcha.Config{
+			Provider:  s.Config.Captcha.Provider,
+			SiteKey:   s.Config.Captcha.SiteKey,
+			SecretKey: s.Config.Captcha.SecretKey,
+		})
+		if verifier == nil {
+			c.Next()
+			return
+		}
+
+		ok, err := verifier.Verify(data.CaptchaToken, c.ClientIP())
+		if err != nil || !ok {
+			c.JSON(http.StatusOK, types.BizVo{Code: types.NotAuthorized, Message: "Captcha Verification Failed"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
 func (s *Server) GetSessionHandle(c *gin.Context) {
 	sessionId := c.GetHeader(types.TokenName)
-	if session, ok := s.ChatSession[sessionId]; ok && session.ClientIP == c.ClientIP() {
+	var chatSession types.ChatSession
+	ok, err := s.Session.Get(chatSessionKeyPrefix+sessionId, &chatSession)
+	if err == nil && ok && chatSession.ClientIP == c.ClientIP() {
 		c.JSON(http.StatusOK, types.BizVo{Code: types.Success})
 	} else {
 		c.JSON(http.StatusOK, types.BizVo{
@@ -257,14 +351,17 @@ func (s *Server) LoginHandle(c *gin.Context) {
 	}
 
 	sessionId := utils.RandString(42)
-	session := sessions.Default(c)
-	session.Set(sessionId, data.Token)
-	err = session.Save()
+	cookieSession := sessions.Default(c)
+	cookieSession.Set(sessionId, data.Token)
+	err = cookieSession.Save()
 	if err != nil {
 		logger.Error("Error for save session: ", err)
 	}
 	// 记录客户端 IP 地址
-	s.ChatSession[sessionId] = types.ChatSession{ClientIP: c.ClientIP(), Username: data.Token, SessionId: sessionId}
+	chatSession := types.ChatSession{ClientIP: c.ClientIP(), Username: data.Token, SessionId: sessionId}
+	if err := s.Session.Put(chatSessionKeyPrefix+sessionId, chatSession, time.Duration(s.Config.Session.MaxAge)*time.Second); err != nil {
+		logger.Error("Error for save chat session: ", err)
+	}
 	c.JSON(http.StatusOK, types.BizVo{Code: types.Success, Data: sessionId})
 }
 
@@ -0,0 +1,16 @@
+package model
+
+// ApiKeyAccessStat 记录每个 ApiKey 最近一次访问时间及失败情况，
+// core/llm 的 Router 据此把最近出错过一次的 Key 暂时排除出候选列表
+type ApiKeyAccessStat struct {
+	Id             uint  `json:"id" gorm:"primarykey"`
+	ApiKeyId       uint  `json:"api_key_id" gorm:"uniqueIndex"`
+	LastAccessedAt int64 `json:"last_accessed_at"`
+	FailCount      int   `json:"fail_count"`
+	// DisabledUntil 为 0 表示当前可用，否则在这个时间戳之前该 Key 会被路由跳过
+	DisabledUntil int64 `json:"disabled_until"`
+}
+
+func (ApiKeyAccessStat) TableName() string {
+	return "chatgpt_api_key_access_stat"
+}
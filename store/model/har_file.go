@@ -0,0 +1,20 @@
+package model
+
+// HarFile 保存管理员上传的 ChatGPT Web 端 .har 导出文件，
+// 用于逆向代理模式下轮询获取会话所需的哨兵 token
+type HarFile struct {
+	Id          uint   `json:"id" gorm:"primarykey"`
+	FileName    string `json:"file_name"`                          // 上传时的原始文件名
+	RawContent  string `json:"raw_content" gorm:"type:mediumtext"` // .har 文件原始内容
+	Email       string `json:"email"`                              // 该 HAR 对应的 ChatGPT 账号，便于管理员区分
+	Enabled     bool   `json:"enabled" gorm:"default:true"`
+	ErrorCount  int    `json:"error_count"`  // 连续请求失败次数；当前不会被写入，失败计数只保存在 core/har.Pool 的内存中
+	CoolingUtil int64  `json:"cooling_util"` // 冷却截止时间戳（秒）；当前不会被写入，冷却状态只保存在 core/har.Pool 的内存中
+	LastUsedAt  int64  `json:"last_used_at"`
+	CreatedAt   int64  `json:"created_at"`
+	UpdatedAt   int64  `json:"updated_at"`
+}
+
+func (HarFile) TableName() string {
+	return "chatgpt_har_file"
+}
@@ -0,0 +1,22 @@
+package model
+
+// LlmProvider 保存一个大模型接入方的配置，ApiKey.Platform 通过名称关联到这里，
+// 供 core/llm 的 Provider 注册表在启动时装配具体实现
+type LlmProvider struct {
+	Id         uint   `json:"id" gorm:"primarykey"`
+	Platform   string `json:"platform"` // openai / azure / claude / duckduckgo / compatible
+	Name       string `json:"name"`     // 管理后台展示用的名称；Platform 为 compatible 时同时是注册表里的 Provider 名称
+	ApiURL     string `json:"api_url"`
+	ApiVersion string `json:"api_version"` // Azure OpenAI 需要的 api-version
+	Model      string `json:"model"`
+	Secret     string `json:"secret"`
+	Enabled    bool   `json:"enabled" gorm:"default:true"`
+	// PriceRank 数值越小越优先被模型路由选中，用于实现「优先用便宜的」的路由策略
+	PriceRank int   `json:"price_rank"`
+	CreatedAt int64 `json:"created_at"`
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+func (LlmProvider) TableName() string {
+	return "chatgpt_llm_provider"
+}